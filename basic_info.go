@@ -1,5 +1,11 @@
 package extinfo
 
+import (
+	"fmt"
+
+	"github.com/doneel/extinfo/cubecode"
+)
+
 // BasicInfoRaw contains the information sent back from the server in their raw form, i.e. no translation from ints to strings, even if possible.
 type BasicInfoRaw struct {
 	NumberOfClients    int    // the number of clients currently connected to the server (players and spectators)
@@ -14,11 +20,12 @@ type BasicInfoRaw struct {
 	Description        string // server description
 }
 
-// BasicInfo contains the parsed information sent back from the server, i.e. game mode and master mode are translated into human readable strings.
+// BasicInfo contains the parsed information sent back from the server, i.e. game mode and master mode are translated into human readable strings, and Description is stripped of cubecode color escapes.
 type BasicInfo struct {
 	BasicInfoRaw
-	GameMode   string // current game mode
-	MasterMode string // the current master mode of the server
+	GameMode    string // current game mode
+	MasterMode  string // the current master mode of the server
+	Description string // server description, with cubecode escapes stripped
 }
 
 // GetBasicInfoRaw queries a Sauerbraten server at addr on port and returns the raw response or an error in case something went wrong. Raw response means that the int values sent as game mode and master mode are NOT translated into the human readable name.
@@ -30,31 +37,60 @@ func (s *Server) GetBasicInfoRaw() (BasicInfoRaw, error) {
 		return basicInfoRaw, err
 	}
 
-	positionInResponse = 0
+	packet := NewPacket(response)
 
 	// first int is BASIC_INFO = 1
-	_ = dumpInt(response)
-	basicInfoRaw.NumberOfClients = dumpInt(response)
+	if _, err := packet.ReadInt(); err != nil {
+		return basicInfoRaw, fmt.Errorf("extinfo: decode basic info header: %w", err)
+	}
+
+	if basicInfoRaw.NumberOfClients, err = packet.ReadInt(); err != nil {
+		return basicInfoRaw, fmt.Errorf("extinfo: decode number of clients: %w", err)
+	}
+
 	// next int is always 5 or 7, the number of additional attributes after the playercount and before the strings for map and description
-	sevenAttributes := false
-	if dumpInt(response) == 7 {
-		sevenAttributes = true
+	numAttributes, err := packet.ReadInt()
+	if err != nil {
+		return basicInfoRaw, fmt.Errorf("extinfo: decode number of attributes: %w", err)
+	}
+	sevenAttributes := numAttributes == 7
+
+	if basicInfoRaw.ProtocolVersion, err = packet.ReadInt(); err != nil {
+		return basicInfoRaw, fmt.Errorf("extinfo: decode protocol version: %w", err)
+	}
+	if basicInfoRaw.GameMode, err = packet.ReadInt(); err != nil {
+		return basicInfoRaw, fmt.Errorf("extinfo: decode game mode: %w", err)
 	}
-	basicInfoRaw.ProtocolVersion = dumpInt(response)
-	basicInfoRaw.GameMode = dumpInt(response)
-	basicInfoRaw.SecsLeft = dumpInt(response)
-	basicInfoRaw.MaxNumberOfClients = dumpInt(response)
-	basicInfoRaw.MasterMode = dumpInt(response)
+	if basicInfoRaw.SecsLeft, err = packet.ReadInt(); err != nil {
+		return basicInfoRaw, fmt.Errorf("extinfo: decode seconds left: %w", err)
+	}
+	if basicInfoRaw.MaxNumberOfClients, err = packet.ReadInt(); err != nil {
+		return basicInfoRaw, fmt.Errorf("extinfo: decode max number of clients: %w", err)
+	}
+	if basicInfoRaw.MasterMode, err = packet.ReadInt(); err != nil {
+		return basicInfoRaw, fmt.Errorf("extinfo: decode master mode: %w", err)
+	}
+
 	if sevenAttributes {
-		if dumpInt(response) == 1 {
-			basicInfoRaw.Paused = true
+		paused, err := packet.ReadInt()
+		if err != nil {
+			return basicInfoRaw, fmt.Errorf("extinfo: decode paused flag: %w", err)
+		}
+		basicInfoRaw.Paused = paused == 1
+
+		if basicInfoRaw.GameSpeed, err = packet.ReadInt(); err != nil {
+			return basicInfoRaw, fmt.Errorf("extinfo: decode game speed: %w", err)
 		}
-		basicInfoRaw.GameSpeed = dumpInt(response)
 	} else {
 		basicInfoRaw.GameSpeed = 100
 	}
-	basicInfoRaw.Map = dumpString(response)
-	basicInfoRaw.Description = dumpString(response)
+
+	if basicInfoRaw.Map, err = packet.ReadString(); err != nil {
+		return basicInfoRaw, fmt.Errorf("extinfo: decode map: %w", err)
+	}
+	if basicInfoRaw.Description, err = packet.ReadString(); err != nil {
+		return basicInfoRaw, fmt.Errorf("extinfo: decode description: %w", err)
+	}
 
 	return basicInfoRaw, nil
 }
@@ -71,6 +107,7 @@ func (s *Server) GetBasicInfo() (BasicInfo, error) {
 	basicInfo.BasicInfoRaw = basicInfoRaw
 	basicInfo.GameMode = getGameModeName(basicInfo.BasicInfoRaw.GameMode)
 	basicInfo.MasterMode = getMasterModeName(basicInfo.BasicInfoRaw.MasterMode)
+	basicInfo.Description = cubecode.SanitizeString(basicInfo.BasicInfoRaw.Description)
 
 	return basicInfo, nil
 }
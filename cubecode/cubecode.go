@@ -0,0 +1,129 @@
+// Package cubecode decodes Sauerbraten's "cube code" text formatting, the
+// \f escape sequences embedded in player names, team names and server
+// descriptions to color and style them in the game's console and HUD.
+package cubecode
+
+// Color identifies a Sauerbraten text color, selected by \f followed by the
+// escape character below.
+type Color rune
+
+// The standard Sauerbraten color table, selected by \f followed by the
+// digit below.
+const (
+	ColorGray    Color = '0'
+	ColorRed     Color = '1'
+	ColorGreen   Color = '2'
+	ColorBlue    Color = '3'
+	ColorYellow  Color = '4'
+	ColorMagenta Color = '5'
+	ColorCyan    Color = '6'
+	ColorOrange  Color = '7'
+)
+
+// DefaultColor is the color text has before any \f escape is seen.
+const DefaultColor = ColorGray
+
+// The two special escapes that manipulate the color stack instead of
+// selecting a color directly: EscapePushColor (\fs) pushes the current
+// color, EscapePopColor (\fS) pops back to the previously pushed one.
+const (
+	EscapePushColor = 's'
+	EscapePopColor  = 'S'
+)
+
+// escapeAltFont (\fa) switches to the alternative (symbol) font for the
+// rest of the string; it carries no color information.
+const escapeAltFont = 'a'
+
+// escapeChar introduces a formatting escape; it is always followed by
+// exactly one more character naming the color or special escape.
+const escapeChar = '\f'
+
+// isColorDigit reports whether c names one of the 8 standard colors.
+func isColorDigit(c rune) bool {
+	return c >= '0' && c <= '7'
+}
+
+// Segment is a run of text sharing a single color.
+type Segment struct {
+	Text  string
+	Color Color
+}
+
+// ParseString splits s into Segments, preserving the color each run of text
+// was sent in. Escape sequences themselves are removed from Text.
+func ParseString(s string) []Segment {
+	segments := []Segment{}
+
+	current := DefaultColor
+	stack := []Color{}
+	var text []rune
+
+	flush := func() {
+		if len(text) == 0 {
+			return
+		}
+		segments = append(segments, Segment{Text: string(text), Color: current})
+		text = nil
+	}
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+
+		if c != escapeChar || i+1 >= len(runes) {
+			text = append(text, c)
+			continue
+		}
+
+		// c is the start of a \f escape; runes[i+1] names it.
+		esc := runes[i+1]
+		i++
+
+		switch {
+		case isColorDigit(esc):
+			flush()
+			current = Color(esc)
+		case esc == EscapePushColor:
+			stack = append(stack, current)
+		case esc == EscapePopColor:
+			if len(stack) > 0 {
+				flush()
+				current = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+		case esc == escapeAltFont:
+			// no color change, nothing to record
+		default:
+			// unknown escape; drop it rather than render it as text
+		}
+	}
+	flush()
+
+	return segments
+}
+
+// SanitizeString strips all \f<X> escape sequences from s, along with any
+// control bytes below 0x20 except for tab, newline and carriage return, so
+// callers get a plain string safe to display or match by content.
+func SanitizeString(s string) string {
+	runes := []rune(s)
+	out := make([]rune, 0, len(runes))
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+
+		if c == escapeChar && i+1 < len(runes) {
+			i++
+			continue
+		}
+
+		if c < 0x20 && c != '\t' && c != '\n' && c != '\r' {
+			continue
+		}
+
+		out = append(out, c)
+	}
+
+	return string(out)
+}
@@ -2,198 +2,148 @@
 package extinfo
 
 import (
-	"errors"
-	"net"
 	"fmt"
+	"net"
+	"sync"
+	"time"
 )
 
-// the current position in a response ([]byte)
-// needed, since values are encoded in variable amount of bytes
-var positionInResponse int
-
 // Constants describing the type of information to query for
 const (
 	EXTENDED_INFORMATION = 0
-	BASIC_INFORMATION = 1
+	BASIC_INFO           = 1
 )
 
 // Constants describing the type of extended information to query for
 const (
-	UPTIME = 0
+	UPTIME      = 0
 	PLAYERSTATS = 1
-	TEAM_SCORE = 2
+	TEAM_SCORE  = 2
 )
 
+// Constants present in the header of every extended information response
+const (
+	EXT_ACK     = -1
+	EXT_VERSION = 105
+)
 
-// GetBasicInfo queries a Sauerbraten server at addr on port and returns the parsed response or an error in case something went wrong. Parsed response means that the int values sent as game mode and master mode are translated into the human readable name, e.g. '12' -> "insta ctf".
-func GetBasicInfo(addr string, port int) (BasicInfo, error) {
-	response, err := queryServer(addr, port, buildRequest(BASIC_INFORMATION, 0, 0))
-	if err != nil {
-		return BasicInfo{}, err
-	}
-
-	positionInResponse = 0
-
-	basicInfo := BasicInfo{}
+// Constants describing whether an extended information request could be answered
+const (
+	EXT_NO_ERROR = 0
+	EXT_ERROR    = 1
+)
 
-	// first int is BASIC_INFORMATION = 1
-	_ = dumpInt(response)
+// Constants describing the kind of PLAYERSTATS response a packet carries
+const (
+	PLAYERSTATS_RESP_IDS   = -10
+	PLAYERSTATS_RESP_STATS = -11
+)
 
-	basicInfo.NumberOfClients = dumpInt(response)
-	// next int is always 5, the number of additional attributes after the playercount and the strings for map and description
-	//numberOfAttributes := dumpInt(response)
-	_ = dumpInt(response)
-	basicInfo.ProtocolVersion = dumpInt(response)
-	basicInfo.GameMode = getGameModeName(dumpInt(response))
-	basicInfo.SecsLeft = dumpInt(response)
-	basicInfo.MaxNumberOfClients = dumpInt(response)
-	basicInfo.MasterMode = getMasterModeName(dumpInt(response))
-	basicInfo.Map = dumpString(response)
-	basicInfo.Description = dumpString(response)
+// Defaults used by NewServer when no matching Option is given.
+const (
+	defaultTimeout    = 5 * time.Second
+	defaultRetries    = 3
+	defaultBufferSize = 1024
+)
 
-	return basicInfo, nil
+// Server represents a Sauerbraten game server queried for extinfo. Use
+// NewServer to construct one; a Server holds a persisted connection that
+// must be released with Close once it is no longer needed. A Server is safe
+// for concurrent use: mu serializes every exchange on conn, so one query's
+// request and response datagram can never be interleaved with another's.
+type Server struct {
+	addr string
+	port int
+
+	timeout    time.Duration
+	retries    int
+	bufferSize int
+	dial       func(network, addr string) (net.Conn, error)
+
+	mu   sync.Mutex
+	conn net.Conn
 }
 
-// GetBasicInfoRaw queries a Sauerbraten server at addr on port and returns the raw response or an error in case something went wrong. Raw response means that the int values sent as game mode and master mode are NOT translated into the human readable name.
-func GetBasicInfoRaw(addr string, port int) (BasicInfoRaw, error) {
-	response, err := queryServer(addr, port, buildRequest(BASIC_INFORMATION, 0, 0))
-	if err != nil {
-		return BasicInfoRaw{}, err
+// NewServer returns a Server ready to be queried at addr on port, applying
+// any given Options on top of the defaults. It dials the server immediately,
+// reusing the resulting connection for every subsequent query; callers must
+// call Close once the Server is no longer needed.
+func NewServer(addr string, port int, opts ...Option) (*Server, error) {
+	s := &Server{
+		addr:       addr,
+		port:       port,
+		timeout:    defaultTimeout,
+		retries:    defaultRetries,
+		bufferSize: defaultBufferSize,
+		dial:       net.Dial,
 	}
 
-	positionInResponse = 0
-
-	basicInfoRaw := BasicInfoRaw{}
-
-	// first int is always '1'
-	_ = dumpInt(response)
-	basicInfoRaw.NumberOfClients = dumpInt(response)
-	// next int is always 5, the number of additional attributes after the playercount and the strings for map and description
-	//numberOfAttributes := dumpInt(response)
-	_ = dumpInt(response)
-	basicInfoRaw.ProtocolVersion = dumpInt(response)
-	basicInfoRaw.GameMode = dumpInt(response)
-	basicInfoRaw.SecsLeft = dumpInt(response)
-	basicInfoRaw.MaxNumberOfClients = dumpInt(response)
-	basicInfoRaw.MasterMode = dumpInt(response)
-	basicInfoRaw.Map = dumpString(response)
-	basicInfoRaw.Description = dumpString(response)
-
-	return basicInfoRaw, nil
-}
-
-// GetUptime returns the uptime of the server in seconds.
-func GetUptime(addr string, port int) (int, error) {
-	response, err := queryServer(addr, port, buildRequest(EXTENDED_INFORMATION, UPTIME, 0))
-	if err != nil {
-		return -1, err
+	for _, opt := range opts {
+		opt(s)
 	}
 
-	positionInResponse = 0
-
-	// first int is 0
-	_ = dumpInt(response)
-
-	// next int is EXT_UPTIME = 0
-	_ = dumpInt(response)
-
-	// next int is EXT_ACK = -1
-	_ = dumpInt(response)
-
-	// next int is EXT_VERSION
-	_ = dumpInt(response)
-
-	// next int is the actual uptime
-	uptime := dumpInt(response)
-
-	return uptime, nil
-}
-
-// GetPlayerInfo returns the parsed information about the player with the given clientNum.
-func GetPlayerInfo(addr string, port int, clientNum int) (PlayerInfo, error) {
-	playerInfo := PlayerInfo{}
-	response, err := queryServer(addr, port, buildRequest(EXTENDED_INFORMATION, PLAYERSTATS, clientNum))
+	conn, err := s.dial("udp", fmt.Sprintf("%s:%d", s.addr, s.port))
 	if err != nil {
-		return playerInfo, err
-	}
-
-	if response[5] != 0x00 {
-		// there was an error
-		return playerInfo, errors.New("invalid cn")
+		return nil, fmt.Errorf("extinfo: dial %s:%d: %w", s.addr, s.port, err)
 	}
+	s.conn = conn
 
-	// throw away 7 first ints (EXTENDED_INFORMATION, PLAYERSTATS, clientNum, server ACK byte, server VERSION byte, server NO_ERROR byte, server PLAYERSTATS_RESP_STATS byte)
-	response = response[7:]
-
-	positionInResponse = 0
-
-
-	playerInfo.ClientNum = dumpInt(response)
-	playerInfo.Ping = dumpInt(response)
-	playerInfo.Name = dumpString(response)
-	playerInfo.Team = dumpString(response)
-	playerInfo.Frags = dumpInt(response)
-	playerInfo.Flags = dumpInt(response)
-	playerInfo.Deaths = dumpInt(response)
-	playerInfo.Teamkills = dumpInt(response)
-	playerInfo.Damage = dumpInt(response)
-	playerInfo.Health = dumpInt(response)
-	playerInfo.Armour = dumpInt(response)
-	playerInfo.Weapon = getWeaponName(dumpInt(response))
-	playerInfo.Privilege = getPrivilegeName(dumpInt(response))
-	playerInfo.State = getStateName(dumpInt(response))
-	// IP from next 4 bytes
-	ip := response[positionInResponse:positionInResponse+4]
-	playerInfo.IP = net.IPv4(ip[0], ip[1], ip[2], ip[3])
-
-	return playerInfo, nil
+	return s, nil
 }
 
-// GetPlayerInfoRaw returns the raw information about the player with the given clientNum.
-func GetPlayerInfoRaw(addr string, port int, clientNum int) (PlayerInfoRaw, error) {
-	playerInfoRaw := PlayerInfoRaw{}
-	response, err := queryServer(addr, port, buildRequest(EXTENDED_INFORMATION, PLAYERSTATS, clientNum))
-	if err != nil {
-		return playerInfoRaw, err
-	}
+// Close releases the Server's underlying connection.
+func (s *Server) Close() error {
+	return s.conn.Close()
+}
 
-	if response[5] != 0x00 {
-		// there was an error
-		return playerInfoRaw, errors.New("invalid cn")
+// queryServer sends request to the server's extinfo port and returns the raw
+// response, retrying up to s.retries times if the read times out. Each of
+// the s.retries+1 attempts gets an equal share of s.timeout, so the whole
+// call takes at most s.timeout, matching what WithTimeout documents. s.mu is
+// held for the whole exchange, so a concurrent query can't interleave its
+// request and response with this one's; a response that arrives only after
+// the call has given up stays on the connection and is read by the next query.
+func (s *Server) queryServer(request []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	response := make([]byte, s.bufferSize)
+	start := time.Now()
+	attempts := s.retries + 1
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		deadline := start.Add(s.timeout * time.Duration(attempt+1) / time.Duration(attempts))
+		if err := s.conn.SetDeadline(deadline); err != nil {
+			return nil, fmt.Errorf("extinfo: set deadline for %s:%d: %w", s.addr, s.port, err)
+		}
+
+		if _, err := s.conn.Write(request); err != nil {
+			return nil, fmt.Errorf("extinfo: send request to %s:%d: %w", s.addr, s.port, err)
+		}
+
+		n, err := s.conn.Read(response)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				lastErr = err
+				continue
+			}
+			return nil, fmt.Errorf("extinfo: read response from %s:%d: %w", s.addr, s.port, err)
+		}
+
+		return response[:n], nil
 	}
 
-	// throw away 7 first ints (EXTENDED_INFORMATION, PLAYERSTATS, clientNum, server ACK byte, server VERSION byte, server NO_ERROR byte, server PLAYERSTATS_RESP_STATS byte)
-	response = response[7:]
-	
-	positionInResponse = 0
-
-	playerInfoRaw.ClientNum = dumpInt(response)
-	playerInfoRaw.Ping = dumpInt(response)
-	playerInfoRaw.Name = dumpString(response)
-	playerInfoRaw.Team = dumpString(response)
-	playerInfoRaw.Frags = dumpInt(response)
-	playerInfoRaw.Flags = dumpInt(response)
-	playerInfoRaw.Deaths = dumpInt(response)
-	playerInfoRaw.Teamkills = dumpInt(response)
-	playerInfoRaw.Damage = dumpInt(response)
-	playerInfoRaw.Health = dumpInt(response)
-	playerInfoRaw.Armour = dumpInt(response)
-	playerInfoRaw.Weapon = dumpInt(response)
-	playerInfoRaw.Privilege = dumpInt(response)
-	playerInfoRaw.State = dumpInt(response)
-	// IP from next 4 bytes
-	ip := response[positionInResponse:positionInResponse+4]
-	playerInfoRaw.IP = net.IPv4(ip[0], ip[1], ip[2], ip[3])
-
-	return playerInfoRaw, nil
+	return nil, fmt.Errorf("extinfo: query %s:%d timed out after %d retries: %w", s.addr, s.port, s.retries, lastErr)
 }
 
-func GetAllPlayerInfo(addr string, port int) ([]PlayerInfo, error) {
-	allPlayerInfo := []PlayerInfo{}
-	response, err := queryServer(addr, port, buildRequest(EXTENDED_INFORMATION, PLAYERSTATS, -1))
-	if err != nil {
-		return allPlayerInfo, err
+// buildRequest assembles the byte sequence sent to the server for the given
+// information type. extendedInformationType and clientNum are only
+// meaningful for EXTENDED_INFORMATION requests.
+func buildRequest(informationType int, extendedInformationType int, clientNum int) []byte {
+	if informationType == BASIC_INFO {
+		return []byte{byte(BASIC_INFO)}
 	}
-	fmt.Print(response)
-	return allPlayerInfo, nil
+
+	return []byte{byte(EXTENDED_INFORMATION), byte(extendedInformationType), byte(clientNum)}
 }
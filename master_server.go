@@ -0,0 +1,176 @@
+package extinfo
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults used by NewMasterServer when no matching MasterServerOption is given.
+const (
+	defaultMasterServerAddr    = "sauerbraten.org:28787"
+	defaultMasterServerTimeout = 5 * time.Second
+)
+
+// ServerAddress identifies a game server as reported by a master server.
+type ServerAddress struct {
+	Host string
+	Port int
+}
+
+// MasterServer represents a Sauerbraten master server, which keeps a
+// registry of public game servers. Use NewMasterServer to construct one.
+type MasterServer struct {
+	addr    string
+	timeout time.Duration
+	dial    func(network, addr string) (net.Conn, error)
+}
+
+// MasterServerOption configures a MasterServer constructed by NewMasterServer.
+type MasterServerOption func(*MasterServer)
+
+// WithMasterServerTimeout sets how long List may spend, once connected, sending its request and reading the server list before giving up; connecting itself is not bounded by it. The default is 5 seconds.
+func WithMasterServerTimeout(timeout time.Duration) MasterServerOption {
+	return func(m *MasterServer) {
+		m.timeout = timeout
+	}
+}
+
+// WithMasterServerDialer overrides the function used to connect to the master server, e.g. to inject a fake server in tests. The default is net.Dial.
+func WithMasterServerDialer(dial func(network, addr string) (net.Conn, error)) MasterServerOption {
+	return func(m *MasterServer) {
+		m.dial = dial
+	}
+}
+
+// NewMasterServer returns a MasterServer that queries addr, applying any
+// given MasterServerOptions on top of the defaults. If addr is empty, it
+// defaults to the official sauerbraten.org:28787 master server.
+func NewMasterServer(addr string, opts ...MasterServerOption) *MasterServer {
+	if addr == "" {
+		addr = defaultMasterServerAddr
+	}
+
+	m := &MasterServer{
+		addr:    addr,
+		timeout: defaultMasterServerTimeout,
+		dial:    net.Dial,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// List connects to the master server, requests the list of registered
+// public servers, and returns them.
+func (m *MasterServer) List() ([]ServerAddress, error) {
+	conn, err := m.dial("tcp", m.addr)
+	if err != nil {
+		return nil, fmt.Errorf("extinfo: dial master server %s: %w", m.addr, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(m.timeout)); err != nil {
+		return nil, fmt.Errorf("extinfo: set deadline for master server %s: %w", m.addr, err)
+	}
+
+	if _, err := conn.Write([]byte("list\n")); err != nil {
+		return nil, fmt.Errorf("extinfo: send list request to master server %s: %w", m.addr, err)
+	}
+
+	servers := []ServerAddress{}
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || fields[0] != "addserver" {
+			continue
+		}
+
+		port, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+
+		servers = append(servers, ServerAddress{Host: fields[1], Port: port})
+	}
+	if err := scanner.Err(); err != nil {
+		return servers, fmt.Errorf("extinfo: read server list from master server %s: %w", m.addr, err)
+	}
+
+	return servers, nil
+}
+
+// Result is what PollAll sends for each server it queries.
+type Result struct {
+	Server    ServerAddress
+	BasicInfo BasicInfo
+	Err       error
+}
+
+// PollAll queries GetBasicInfo for every server in servers, using up to
+// workers goroutines concurrently, and streams a Result for each one on the
+// returned channel as soon as it is available. The channel is closed once
+// every server has been queried or ctx is done. A workers value less than 1
+// is treated as 1.
+func PollAll(ctx context.Context, servers []ServerAddress, workers int) <-chan Result {
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan ServerAddress)
+	results := make(chan Result)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for addr := range jobs {
+				result := Result{Server: addr}
+
+				server, err := NewServer(addr.Host, addr.Port)
+				if err != nil {
+					result.Err = err
+				} else {
+					result.BasicInfo, result.Err = server.GetBasicInfo()
+					server.Close()
+				}
+
+				select {
+				case results <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+
+		for _, addr := range servers {
+			select {
+			case jobs <- addr:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
@@ -0,0 +1,146 @@
+package extinfo
+
+// getGameModeName translates a game mode int sent by the server into its
+// human readable name.
+func getGameModeName(gameMode int) string {
+	switch gameMode {
+	case 0:
+		return "ffa/default"
+	case 1:
+		return "coop edit"
+	case 2:
+		return "teamplay"
+	case 3:
+		return "instagib"
+	case 4:
+		return "instagib team"
+	case 5:
+		return "efficiency"
+	case 6:
+		return "efficiency team"
+	case 7:
+		return "tactics"
+	case 8:
+		return "tactics team"
+	case 9:
+		return "capture"
+	case 10:
+		return "regen capture"
+	case 11:
+		return "ctf"
+	case 12:
+		return "insta ctf"
+	case 13:
+		return "protect"
+	case 14:
+		return "insta protect"
+	case 15:
+		return "hold"
+	case 16:
+		return "insta hold"
+	case 17:
+		return "efficiency ctf"
+	case 18:
+		return "efficiency protect"
+	case 19:
+		return "efficiency hold"
+	case 20:
+		return "collect"
+	case 21:
+		return "insta collect"
+	case 22:
+		return "efficiency collect"
+	default:
+		return "unknown"
+	}
+}
+
+// getMasterModeName translates a master mode int sent by the server into its
+// human readable name.
+func getMasterModeName(masterMode int) string {
+	switch masterMode {
+	case -1:
+		return "auth"
+	case 0:
+		return "open"
+	case 1:
+		return "veto"
+	case 2:
+		return "locked"
+	case 3:
+		return "private"
+	case 4:
+		return "password"
+	default:
+		return "unknown"
+	}
+}
+
+// getWeaponName translates a weapon int sent by the server into its human
+// readable name.
+func getWeaponName(weapon int) string {
+	switch weapon {
+	case 0:
+		return "fist"
+	case 1:
+		return "shotgun"
+	case 2:
+		return "chaingun"
+	case 3:
+		return "rocket launcher"
+	case 4:
+		return "rifle"
+	case 5:
+		return "grenade launcher"
+	case 6:
+		return "pistol"
+	case 7:
+		return "fireball"
+	case 8:
+		return "iceball"
+	case 9:
+		return "slimeball"
+	case 10:
+		return "bite"
+	case 11:
+		return "barrel"
+	default:
+		return "unknown"
+	}
+}
+
+// getPrivilegeName translates a privilege int sent by the server into its
+// human readable name.
+func getPrivilegeName(privilege int) string {
+	switch privilege {
+	case 0:
+		return "none"
+	case 1:
+		return "master"
+	case 2:
+		return "admin"
+	default:
+		return "unknown"
+	}
+}
+
+// getStateName translates a player state int sent by the server into its
+// human readable name.
+func getStateName(state int) string {
+	switch state {
+	case 0:
+		return "alive"
+	case 1:
+		return "dead"
+	case 2:
+		return "spawning"
+	case 3:
+		return "lagged"
+	case 4:
+		return "editing"
+	case 5:
+		return "spectator"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,42 @@
+package extinfo
+
+import (
+	"net"
+	"time"
+)
+
+// Option configures a Server constructed by NewServer.
+type Option func(*Server)
+
+// WithTimeout sets how long a single query may take, including retries,
+// before queryServer gives up and returns an error. The default is 5 seconds.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.timeout = timeout
+	}
+}
+
+// WithRetries sets how many times queryServer retries a query after a
+// read times out, to ride out transient UDP packet loss. The default is 3.
+func WithRetries(retries int) Option {
+	return func(s *Server) {
+		s.retries = retries
+	}
+}
+
+// WithDialer overrides the function used to establish the Server's
+// connection, e.g. to inject a fake server in tests or to tunnel the query
+// over a SOCKS proxy. The default is net.Dial.
+func WithDialer(dial func(network, addr string) (net.Conn, error)) Option {
+	return func(s *Server) {
+		s.dial = dial
+	}
+}
+
+// WithBufferSize sets the size of the buffer used to read responses into.
+// The default of 1024 bytes comfortably fits every extinfo response.
+func WithBufferSize(size int) Option {
+	return func(s *Server) {
+		s.bufferSize = size
+	}
+}
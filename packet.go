@@ -0,0 +1,97 @@
+package extinfo
+
+import (
+	"fmt"
+	"io"
+)
+
+// Packet wraps a response buffer together with a read cursor, decoding the
+// variable-length ints and null-terminated strings used by the Sauerbraten
+// extinfo wire format. Unlike the old package-level position counter, a
+// Packet is self-contained, so concurrent callers can decode their own
+// responses without stepping on each other's cursor.
+type Packet struct {
+	buf []byte
+	pos int
+}
+
+// NewPacket wraps buf for reading, starting at the first byte.
+func NewPacket(buf []byte) *Packet {
+	return &Packet{buf: buf}
+}
+
+// HasRemaining reports whether there is at least one more byte to read.
+func (p *Packet) HasRemaining() bool {
+	return p.pos < len(p.buf)
+}
+
+// Remaining returns the number of unread bytes left in the packet.
+func (p *Packet) Remaining() int {
+	return len(p.buf) - p.pos
+}
+
+// Skip advances the read cursor by n bytes without interpreting them.
+func (p *Packet) Skip(n int) {
+	p.pos += n
+}
+
+// ReadByte reads and returns the next raw byte.
+func (p *Packet) ReadByte() (byte, error) {
+	if p.pos >= len(p.buf) {
+		return 0, fmt.Errorf("extinfo: read byte at offset %d: %w", p.pos, io.ErrUnexpectedEOF)
+	}
+
+	b := p.buf[p.pos]
+	p.pos++
+
+	return b, nil
+}
+
+// ReadInt reads a Sauerbraten variable-length signed int: a single byte for
+// -127..127, a 0x80 marker followed by 2 little-endian bytes for 16-bit
+// values, or a 0x81 marker followed by 4 little-endian bytes for 32-bit
+// values.
+func (p *Packet) ReadInt() (int, error) {
+	b, err := p.ReadByte()
+	if err != nil {
+		return 0, fmt.Errorf("extinfo: read int: %w", err)
+	}
+
+	switch int8(b) {
+	case -128: // 0x80: a 16-bit value follows
+		if p.pos+2 > len(p.buf) {
+			return 0, fmt.Errorf("extinfo: read 16-bit int at offset %d: %w", p.pos, io.ErrUnexpectedEOF)
+		}
+		v := int(int16(uint16(p.buf[p.pos]) | uint16(p.buf[p.pos+1])<<8))
+		p.pos += 2
+		return v, nil
+
+	case -127: // 0x81: a 32-bit value follows
+		if p.pos+4 > len(p.buf) {
+			return 0, fmt.Errorf("extinfo: read 32-bit int at offset %d: %w", p.pos, io.ErrUnexpectedEOF)
+		}
+		v := int(int32(uint32(p.buf[p.pos]) | uint32(p.buf[p.pos+1])<<8 | uint32(p.buf[p.pos+2])<<16 | uint32(p.buf[p.pos+3])<<24))
+		p.pos += 4
+		return v, nil
+
+	default:
+		return int(int8(b)), nil
+	}
+}
+
+// ReadString reads a null-terminated string.
+func (p *Packet) ReadString() (string, error) {
+	start := p.pos
+
+	for {
+		if p.pos >= len(p.buf) {
+			return "", fmt.Errorf("extinfo: read string starting at offset %d: %w", start, io.ErrUnexpectedEOF)
+		}
+		if p.buf[p.pos] == 0x00 {
+			s := string(p.buf[start:p.pos])
+			p.pos++
+			return s, nil
+		}
+		p.pos++
+	}
+}
@@ -0,0 +1,240 @@
+package extinfo
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/doneel/extinfo/cubecode"
+)
+
+// PlayerInfoRaw contains the information sent back from the server about a single player in their raw form, i.e. no translation from ints to strings, even if possible.
+type PlayerInfoRaw struct {
+	ClientNum int    // the client number of the player
+	Ping      int    // the player's ping to the server
+	Name      string // the player's name
+	Team      string // the name of the team the player is on
+	Frags     int    // the number of frags
+	Flags     int    // the number of flags collected (in modes with flags)
+	Deaths    int    // the number of deaths
+	Teamkills int    // the number of teamkills
+	Damage    int    // the damage done this round
+	Health    int    // the player's current health
+	Armour    int    // the player's current armour
+	Weapon    int    // the weapon the player is currently using
+	Privilege int    // the player's privilege level
+	State     int    // the player's state, e.g. dead or alive
+	IP        net.IP // the player's IP, with the last byte zeroed out
+}
+
+// PlayerInfo contains the parsed information sent back from the server about a single player, i.e. weapon, privilege and state are translated into human readable strings, and Name and Team are stripped of cubecode color escapes.
+type PlayerInfo struct {
+	PlayerInfoRaw
+	Name      string // the player's name, with cubecode escapes stripped
+	Team      string // the name of the team the player is on, with cubecode escapes stripped
+	Weapon    string // the weapon the player is currently using
+	Privilege string // the player's privilege level
+	State     string // the player's state, e.g. dead or alive
+}
+
+// GetPlayerInfoRaw returns the raw information about the player with the given clientNum, or an error if the server rejects the clientNum or the response cannot be decoded.
+func (s *Server) GetPlayerInfoRaw(clientNum int) (PlayerInfoRaw, error) {
+	response, err := s.queryServer(buildRequest(EXTENDED_INFORMATION, PLAYERSTATS, clientNum))
+	if err != nil {
+		return PlayerInfoRaw{}, err
+	}
+
+	return decodePlayerInfoRaw(response)
+}
+
+// decodePlayerInfoRaw decodes a single PLAYERSTATS response packet, as sent
+// in reply to both a single-client query and as part of the per-client
+// packets following a GetAllPlayerInfo query.
+func decodePlayerInfoRaw(response []byte) (PlayerInfoRaw, error) {
+	playerInfoRaw := PlayerInfoRaw{}
+
+	packet := NewPacket(response)
+
+	// first 5 ints are EXTENDED_INFORMATION, PLAYERSTATS, clientNum, EXT_ACK, EXT_VERSION
+	packet.Skip(5)
+
+	errorCode, err := packet.ReadInt()
+	if err != nil {
+		return playerInfoRaw, fmt.Errorf("extinfo: decode player info error code: %w", err)
+	}
+	if errorCode != EXT_NO_ERROR {
+		return playerInfoRaw, fmt.Errorf("extinfo: server rejected client number")
+	}
+
+	// next int is PLAYERSTATS_RESP_STATS
+	if _, err := packet.ReadInt(); err != nil {
+		return playerInfoRaw, fmt.Errorf("extinfo: decode player stats marker: %w", err)
+	}
+
+	if playerInfoRaw.ClientNum, err = packet.ReadInt(); err != nil {
+		return playerInfoRaw, fmt.Errorf("extinfo: decode client number: %w", err)
+	}
+	if playerInfoRaw.Ping, err = packet.ReadInt(); err != nil {
+		return playerInfoRaw, fmt.Errorf("extinfo: decode ping: %w", err)
+	}
+	if playerInfoRaw.Name, err = packet.ReadString(); err != nil {
+		return playerInfoRaw, fmt.Errorf("extinfo: decode name: %w", err)
+	}
+	if playerInfoRaw.Team, err = packet.ReadString(); err != nil {
+		return playerInfoRaw, fmt.Errorf("extinfo: decode team: %w", err)
+	}
+	if playerInfoRaw.Frags, err = packet.ReadInt(); err != nil {
+		return playerInfoRaw, fmt.Errorf("extinfo: decode frags: %w", err)
+	}
+	if playerInfoRaw.Flags, err = packet.ReadInt(); err != nil {
+		return playerInfoRaw, fmt.Errorf("extinfo: decode flags: %w", err)
+	}
+	if playerInfoRaw.Deaths, err = packet.ReadInt(); err != nil {
+		return playerInfoRaw, fmt.Errorf("extinfo: decode deaths: %w", err)
+	}
+	if playerInfoRaw.Teamkills, err = packet.ReadInt(); err != nil {
+		return playerInfoRaw, fmt.Errorf("extinfo: decode teamkills: %w", err)
+	}
+	if playerInfoRaw.Damage, err = packet.ReadInt(); err != nil {
+		return playerInfoRaw, fmt.Errorf("extinfo: decode damage: %w", err)
+	}
+	if playerInfoRaw.Health, err = packet.ReadInt(); err != nil {
+		return playerInfoRaw, fmt.Errorf("extinfo: decode health: %w", err)
+	}
+	if playerInfoRaw.Armour, err = packet.ReadInt(); err != nil {
+		return playerInfoRaw, fmt.Errorf("extinfo: decode armour: %w", err)
+	}
+	if playerInfoRaw.Weapon, err = packet.ReadInt(); err != nil {
+		return playerInfoRaw, fmt.Errorf("extinfo: decode weapon: %w", err)
+	}
+	if playerInfoRaw.Privilege, err = packet.ReadInt(); err != nil {
+		return playerInfoRaw, fmt.Errorf("extinfo: decode privilege: %w", err)
+	}
+	if playerInfoRaw.State, err = packet.ReadInt(); err != nil {
+		return playerInfoRaw, fmt.Errorf("extinfo: decode state: %w", err)
+	}
+
+	ip := make([]byte, 4)
+	for i := range ip {
+		if ip[i], err = packet.ReadByte(); err != nil {
+			return playerInfoRaw, fmt.Errorf("extinfo: decode ip: %w", err)
+		}
+	}
+	playerInfoRaw.IP = net.IPv4(ip[0], ip[1], ip[2], ip[3])
+
+	return playerInfoRaw, nil
+}
+
+// GetPlayerInfo returns the parsed information about the player with the given clientNum.
+func (s *Server) GetPlayerInfo(clientNum int) (PlayerInfo, error) {
+	playerInfo := PlayerInfo{}
+
+	playerInfoRaw, err := s.GetPlayerInfoRaw(clientNum)
+	if err != nil {
+		return playerInfo, err
+	}
+
+	playerInfo.PlayerInfoRaw = playerInfoRaw
+	playerInfo.Name = cubecode.SanitizeString(playerInfoRaw.Name)
+	playerInfo.Team = cubecode.SanitizeString(playerInfoRaw.Team)
+	playerInfo.Weapon = getWeaponName(playerInfoRaw.Weapon)
+	playerInfo.Privilege = getPrivilegeName(playerInfoRaw.Privilege)
+	playerInfo.State = getStateName(playerInfoRaw.State)
+
+	return playerInfo, nil
+}
+
+// GetUptime returns the uptime of the server in seconds.
+func (s *Server) GetUptime() (int, error) {
+	response, err := s.queryServer(buildRequest(EXTENDED_INFORMATION, UPTIME, 0))
+	if err != nil {
+		return -1, err
+	}
+
+	packet := NewPacket(response)
+
+	// first 4 ints are EXTENDED_INFORMATION, UPTIME, EXT_ACK, EXT_VERSION
+	packet.Skip(4)
+
+	uptime, err := packet.ReadInt()
+	if err != nil {
+		return -1, fmt.Errorf("extinfo: decode uptime: %w", err)
+	}
+
+	return uptime, nil
+}
+
+// GetAllPlayerInfo returns the parsed information about every player currently on the server. The server first answers with the list of connected client numbers, then sends one PLAYERSTATS packet per client; GetAllPlayerInfo collects all of them, in any order, until every client has answered or the Server's timeout elapses. s.mu is held for the whole exchange, so the per-client packets it reads can never be stolen by a concurrent query on the same Server.
+func (s *Server) GetAllPlayerInfo() ([]PlayerInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	allPlayerInfo := []PlayerInfo{}
+
+	deadline := time.Now().Add(s.timeout)
+	if err := s.conn.SetReadDeadline(deadline); err != nil {
+		return allPlayerInfo, fmt.Errorf("extinfo: set read deadline: %w", err)
+	}
+
+	if _, err := s.conn.Write(buildRequest(EXTENDED_INFORMATION, PLAYERSTATS, -1)); err != nil {
+		return allPlayerInfo, fmt.Errorf("extinfo: send request to %s:%d: %w", s.addr, s.port, err)
+	}
+
+	buf := make([]byte, s.bufferSize)
+
+	n, err := s.conn.Read(buf)
+	if err != nil {
+		return allPlayerInfo, fmt.Errorf("extinfo: read client number list from %s:%d: %w", s.addr, s.port, err)
+	}
+
+	packet := NewPacket(buf[:n])
+	// header: EXTENDED_INFORMATION, PLAYERSTATS, -1, EXT_ACK, EXT_VERSION, NO_ERROR, PLAYERSTATS_RESP_IDS
+	packet.Skip(7)
+
+	pending := map[int]bool{}
+	for packet.HasRemaining() {
+		cn, err := packet.ReadInt()
+		if err != nil {
+			return allPlayerInfo, fmt.Errorf("extinfo: decode client number list: %w", err)
+		}
+		pending[cn] = true
+	}
+
+	for len(pending) > 0 {
+		n, err := s.conn.Read(buf)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				break
+			}
+			return allPlayerInfo, fmt.Errorf("extinfo: read player stats from %s:%d: %w", s.addr, s.port, err)
+		}
+
+		playerInfoRaw, err := decodePlayerInfoRaw(buf[:n])
+		if err != nil || !pending[playerInfoRaw.ClientNum] {
+			// malformed, duplicate or unrequested packet; keep waiting for the rest
+			continue
+		}
+
+		allPlayerInfo = append(allPlayerInfo, PlayerInfo{
+			PlayerInfoRaw: playerInfoRaw,
+			Name:          cubecode.SanitizeString(playerInfoRaw.Name),
+			Team:          cubecode.SanitizeString(playerInfoRaw.Team),
+			Weapon:        getWeaponName(playerInfoRaw.Weapon),
+			Privilege:     getPrivilegeName(playerInfoRaw.Privilege),
+			State:         getStateName(playerInfoRaw.State),
+		})
+		delete(pending, playerInfoRaw.ClientNum)
+	}
+
+	if len(pending) > 0 {
+		missing := make([]int, 0, len(pending))
+		for cn := range pending {
+			missing = append(missing, cn)
+		}
+		sort.Ints(missing)
+		return allPlayerInfo, fmt.Errorf("extinfo: client numbers %v did not respond in time", missing)
+	}
+
+	return allPlayerInfo, nil
+}
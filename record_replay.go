@@ -0,0 +1,254 @@
+package extinfo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// recordingMagic and recordingVersion identify the framed format written by
+// NewRecordingServer and read back by NewReplayServer.
+var recordingMagic = [4]byte{'E', 'X', 'T', 'I'}
+
+const recordingVersion = 1
+
+// Directions of the requests and responses stored in a recording.
+const (
+	directionOutbound byte = 0
+	directionInbound  byte = 1
+)
+
+// writeRecordingHeader writes the magic bytes and version identifying the
+// framed recording format to w.
+func writeRecordingHeader(w io.Writer) error {
+	if _, err := w.Write(recordingMagic[:]); err != nil {
+		return err
+	}
+	_, err := w.Write([]byte{recordingVersion})
+	return err
+}
+
+// readRecordingHeader reads and validates the header written by
+// writeRecordingHeader.
+func readRecordingHeader(r io.Reader) error {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return fmt.Errorf("extinfo: read recording header: %w", err)
+	}
+	if !bytes.Equal(header[:4], recordingMagic[:]) {
+		return errors.New("extinfo: not an extinfo recording")
+	}
+	if header[4] != recordingVersion {
+		return fmt.Errorf("extinfo: unsupported recording version %d", header[4])
+	}
+
+	return nil
+}
+
+// writeRecord appends a single {timestamp, direction, length, payload}
+// record to w.
+func writeRecord(w io.Writer, direction byte, payload []byte) error {
+	var header [13]byte
+	binary.BigEndian.PutUint64(header[0:8], uint64(time.Now().UnixNano()))
+	header[8] = direction
+	binary.BigEndian.PutUint32(header[9:13], uint32(len(payload)))
+
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// readRecord reads a single record written by writeRecord.
+func readRecord(r io.Reader) (direction byte, payload []byte, err error) {
+	var header [13]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	direction = header[8]
+	length := binary.BigEndian.Uint32(header[9:13])
+
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+
+	return direction, payload, nil
+}
+
+// recordingConn wraps a live net.Conn, appending every request it sends and
+// response it receives to w as they happen.
+type recordingConn struct {
+	net.Conn
+	w           io.Writer
+	wroteHeader bool
+}
+
+func (c *recordingConn) ensureHeader() error {
+	if c.wroteHeader {
+		return nil
+	}
+	if err := writeRecordingHeader(c.w); err != nil {
+		return err
+	}
+	c.wroteHeader = true
+	return nil
+}
+
+func (c *recordingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if err != nil {
+		return n, err
+	}
+
+	if headerErr := c.ensureHeader(); headerErr != nil {
+		return n, fmt.Errorf("extinfo: record: write recording header: %w", headerErr)
+	}
+	if recErr := writeRecord(c.w, directionOutbound, b[:n]); recErr != nil {
+		return n, fmt.Errorf("extinfo: record: write outbound record: %w", recErr)
+	}
+
+	return n, nil
+}
+
+func (c *recordingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if err != nil {
+		return n, err
+	}
+
+	if headerErr := c.ensureHeader(); headerErr != nil {
+		return n, fmt.Errorf("extinfo: record: write recording header: %w", headerErr)
+	}
+	if recErr := writeRecord(c.w, directionInbound, b[:n]); recErr != nil {
+		return n, fmt.Errorf("extinfo: record: write inbound record: %w", recErr)
+	}
+
+	return n, nil
+}
+
+// NewRecordingServer wraps s so that every request it sends and response it
+// receives is also appended to w in a framed format that NewReplayServer can
+// later read back. The returned Server queries the real server exactly like
+// s; only the recording is new.
+func NewRecordingServer(s *Server, w io.Writer) *Server {
+	return &Server{
+		addr:       s.addr,
+		port:       s.port,
+		timeout:    s.timeout,
+		retries:    s.retries,
+		bufferSize: s.bufferSize,
+		dial:       s.dial,
+		conn:       &recordingConn{Conn: s.conn, w: w},
+	}
+}
+
+// replayTimeoutError is returned by replayConn.Read once the recorded
+// stream is exhausted, so callers that stop reading on a timed-out net.Conn
+// (like GetAllPlayerInfo) behave the same way against a replay as they do
+// against a live server whose remaining players never answered.
+type replayTimeoutError struct{}
+
+func (replayTimeoutError) Error() string   { return "extinfo: replay: no more recorded packets" }
+func (replayTimeoutError) Timeout() bool   { return true }
+func (replayTimeoutError) Temporary() bool { return false }
+
+// replayConn implements net.Conn by replaying a previously recorded stream:
+// writes are matched against the recorded requests, and reads return the
+// recorded responses in order.
+type replayConn struct {
+	r          *bufio.Reader
+	readHeader bool
+	pending    []byte
+}
+
+func newReplayConn(r io.Reader) *replayConn {
+	return &replayConn{r: bufio.NewReader(r)}
+}
+
+func (c *replayConn) ensureHeader() error {
+	if c.readHeader {
+		return nil
+	}
+	if err := readRecordingHeader(c.r); err != nil {
+		return err
+	}
+	c.readHeader = true
+	return nil
+}
+
+func (c *replayConn) Write(b []byte) (int, error) {
+	if err := c.ensureHeader(); err != nil {
+		return 0, err
+	}
+
+	direction, payload, err := readRecord(c.r)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return 0, replayTimeoutError{}
+		}
+		return 0, fmt.Errorf("extinfo: replay: read outbound record: %w", err)
+	}
+	if direction != directionOutbound {
+		return 0, errors.New("extinfo: replay: expected an outbound record next")
+	}
+	if !bytes.Equal(payload, b) {
+		return 0, fmt.Errorf("extinfo: replay: request %x does not match recorded request %x", b, payload)
+	}
+
+	return len(b), nil
+}
+
+func (c *replayConn) Read(b []byte) (int, error) {
+	if len(c.pending) == 0 {
+		if err := c.ensureHeader(); err != nil {
+			return 0, err
+		}
+
+		direction, payload, err := readRecord(c.r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return 0, replayTimeoutError{}
+			}
+			return 0, fmt.Errorf("extinfo: replay: read inbound record: %w", err)
+		}
+		if direction != directionInbound {
+			return 0, errors.New("extinfo: replay: expected an inbound record next")
+		}
+
+		c.pending = payload
+	}
+
+	n := copy(b, c.pending)
+	c.pending = c.pending[n:]
+
+	return n, nil
+}
+
+func (c *replayConn) Close() error                       { return nil }
+func (c *replayConn) LocalAddr() net.Addr                { return nil }
+func (c *replayConn) RemoteAddr() net.Addr               { return nil }
+func (c *replayConn) SetDeadline(t time.Time) error      { return nil }
+func (c *replayConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *replayConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// NewReplayServer returns a Server that answers every query from the framed
+// recording read from r, produced by NewRecordingServer, instead of
+// querying a live server. This lets tests exercise every decoder branch
+// (5-attr vs 7-attr basic info, paused/unpaused, multi-packet all-players,
+// malformed truncations, ...) from a captured fixture.
+func NewReplayServer(r io.Reader) *Server {
+	return &Server{
+		timeout:    defaultTimeout,
+		retries:    0,
+		bufferSize: defaultBufferSize,
+		conn:       newReplayConn(r),
+	}
+}
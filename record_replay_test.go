@@ -0,0 +1,382 @@
+package extinfo
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+// encodeInt encodes v using the Sauerbraten variable-length int scheme, the
+// inverse of Packet.ReadInt, so fixtures below can be built by hand.
+func encodeInt(v int) []byte {
+	if v >= -127 && v <= 127 {
+		return []byte{byte(int8(v))}
+	}
+	if v >= -32768 && v <= 32767 {
+		u := uint16(int16(v))
+		return []byte{0x80, byte(u), byte(u >> 8)}
+	}
+	u := uint32(int32(v))
+	return []byte{0x81, byte(u), byte(u >> 8), byte(u >> 16), byte(u >> 24)}
+}
+
+func encodeInts(vs ...int) []byte {
+	buf := []byte{}
+	for _, v := range vs {
+		buf = append(buf, encodeInt(v)...)
+	}
+	return buf
+}
+
+func encodeString(s string) []byte {
+	return append([]byte(s), 0x00)
+}
+
+func buildBasicInfoResponse(numClients, protocolVersion, gameMode, secsLeft, maxClients, masterMode int, sevenAttributes, paused bool, gameSpeed int, mapName, description string) []byte {
+	numAttributes := 5
+	if sevenAttributes {
+		numAttributes = 7
+	}
+
+	buf := encodeInts(BASIC_INFO, numClients, numAttributes, protocolVersion, gameMode, secsLeft, maxClients, masterMode)
+	if sevenAttributes {
+		pausedInt := 0
+		if paused {
+			pausedInt = 1
+		}
+		buf = append(buf, encodeInts(pausedInt, gameSpeed)...)
+	}
+	buf = append(buf, encodeString(mapName)...)
+	buf = append(buf, encodeString(description)...)
+
+	return buf
+}
+
+func buildPlayerStatsErrorResponse(clientNum int) []byte {
+	return encodeInts(EXTENDED_INFORMATION, PLAYERSTATS, clientNum, EXT_ACK, EXT_VERSION, EXT_ERROR)
+}
+
+func buildPlayerStatsResponse(clientNum, ping int, name, team string, frags, flags, deaths, teamkills, damage, health, armour, weapon, privilege, state int, ip [4]byte) []byte {
+	buf := encodeInts(EXTENDED_INFORMATION, PLAYERSTATS, clientNum, EXT_ACK, EXT_VERSION, EXT_NO_ERROR, PLAYERSTATS_RESP_STATS, clientNum, ping)
+	buf = append(buf, encodeString(name)...)
+	buf = append(buf, encodeString(team)...)
+	buf = append(buf, encodeInts(frags, flags, deaths, teamkills, damage, health, armour, weapon, privilege, state)...)
+	buf = append(buf, ip[:]...)
+
+	return buf
+}
+
+func buildCNListResponse(cns []int) []byte {
+	buf := encodeInts(EXTENDED_INFORMATION, PLAYERSTATS, -1, EXT_ACK, EXT_VERSION, EXT_NO_ERROR, PLAYERSTATS_RESP_IDS)
+	buf = append(buf, encodeInts(cns...)...)
+
+	return buf
+}
+
+type fixtureTeam struct {
+	name  string
+	score int
+	bases []int
+}
+
+func buildTeamScoreResponse(isTeamMode bool, secsLeft int, teams []fixtureTeam) []byte {
+	isTeamModeInt := 0
+	if isTeamMode {
+		isTeamModeInt = 1
+	}
+
+	buf := encodeInts(EXTENDED_INFORMATION, TEAM_SCORE, EXT_ACK, EXT_VERSION, EXT_NO_ERROR, isTeamModeInt, secsLeft)
+	for _, team := range teams {
+		buf = append(buf, encodeString(team.name)...)
+		buf = append(buf, encodeInts(team.score, len(team.bases))...)
+		buf = append(buf, encodeInts(team.bases...)...)
+	}
+
+	return buf
+}
+
+// buildTeamScoreResponseWithBaseCount builds a single-team TEAM_SCORE
+// response with an explicit (possibly invalid) base count, independent of
+// how many base indices actually follow it.
+func buildTeamScoreResponseWithBaseCount(name string, score, baseCount int) []byte {
+	buf := encodeInts(EXTENDED_INFORMATION, TEAM_SCORE, EXT_ACK, EXT_VERSION, EXT_NO_ERROR, 1, 60)
+	buf = append(buf, encodeString(name)...)
+	buf = append(buf, encodeInts(score, baseCount)...)
+
+	return buf
+}
+
+type fixtureRecord struct {
+	direction byte
+	payload   []byte
+}
+
+func outbound(payload []byte) fixtureRecord {
+	return fixtureRecord{direction: directionOutbound, payload: payload}
+}
+func inbound(payload []byte) fixtureRecord {
+	return fixtureRecord{direction: directionInbound, payload: payload}
+}
+
+// newReplayFixture frames records in the same format NewRecordingServer
+// writes, so NewReplayServer can read them back.
+func newReplayFixture(t *testing.T, records ...fixtureRecord) *bytes.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := writeRecordingHeader(&buf); err != nil {
+		t.Fatalf("write recording header: %v", err)
+	}
+	for _, record := range records {
+		if err := writeRecord(&buf, record.direction, record.payload); err != nil {
+			t.Fatalf("write record: %v", err)
+		}
+	}
+
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestReplayGetBasicInfoFiveAttributes(t *testing.T) {
+	request := buildRequest(BASIC_INFO, 0, 0)
+	response := buildBasicInfoResponse(3, 260, 12, 300, 16, 0, false, false, 0, "ot_bridge", "a \f0red\f7 server")
+
+	server := NewReplayServer(newReplayFixture(t, outbound(request), inbound(response)))
+
+	info, err := server.GetBasicInfo()
+	if err != nil {
+		t.Fatalf("GetBasicInfo: %v", err)
+	}
+
+	if info.NumberOfClients != 3 || info.GameSpeed != 100 || info.Paused {
+		t.Fatalf("unexpected basic info: %+v", info)
+	}
+	if info.GameMode != "insta ctf" {
+		t.Fatalf("GameMode = %q, want %q", info.GameMode, "insta ctf")
+	}
+	if info.Map != "ot_bridge" {
+		t.Fatalf("Map = %q, want %q", info.Map, "ot_bridge")
+	}
+	if info.Description != "a red server" {
+		t.Fatalf("Description = %q, want cubecode escapes stripped", info.Description)
+	}
+}
+
+func TestReplayGetBasicInfoSevenAttributesPaused(t *testing.T) {
+	request := buildRequest(BASIC_INFO, 0, 0)
+	response := buildBasicInfoResponse(0, 260, 2, 0, 16, 1, true, true, 133, "complex", "paused teamplay")
+
+	server := NewReplayServer(newReplayFixture(t, outbound(request), inbound(response)))
+
+	info, err := server.GetBasicInfoRaw()
+	if err != nil {
+		t.Fatalf("GetBasicInfoRaw: %v", err)
+	}
+
+	if !info.Paused {
+		t.Fatalf("Paused = false, want true")
+	}
+	if info.GameSpeed != 133 {
+		t.Fatalf("GameSpeed = %d, want 133", info.GameSpeed)
+	}
+}
+
+func TestReplayGetBasicInfoMasterModeVariants(t *testing.T) {
+	cases := []struct {
+		masterMode int
+		want       string
+	}{
+		{-1, "auth"},
+		{0, "open"},
+		{1, "veto"},
+		{2, "locked"},
+		{3, "private"},
+		{4, "password"},
+		{99, "unknown"},
+	}
+
+	for _, c := range cases {
+		request := buildRequest(BASIC_INFO, 0, 0)
+		response := buildBasicInfoResponse(1, 260, 0, 0, 16, c.masterMode, false, false, 0, "hashbang", "")
+
+		server := NewReplayServer(newReplayFixture(t, outbound(request), inbound(response)))
+
+		info, err := server.GetBasicInfo()
+		if err != nil {
+			t.Fatalf("masterMode %d: GetBasicInfo: %v", c.masterMode, err)
+		}
+		if info.MasterMode != c.want {
+			t.Errorf("masterMode %d: MasterMode = %q, want %q", c.masterMode, info.MasterMode, c.want)
+		}
+	}
+}
+
+func TestReplayGetBasicInfoTruncated(t *testing.T) {
+	request := buildRequest(BASIC_INFO, 0, 0)
+	response := buildBasicInfoResponse(3, 260, 12, 300, 16, 0, false, false, 0, "ot_bridge", "description")
+	truncated := response[:len(response)-4]
+
+	server := NewReplayServer(newReplayFixture(t, outbound(request), inbound(truncated)))
+
+	if _, err := server.GetBasicInfo(); err == nil {
+		t.Fatal("GetBasicInfo with a truncated response: got nil error, want a decode error")
+	}
+}
+
+func TestReplayGetPlayerInfoInvalidClientNum(t *testing.T) {
+	request := buildRequest(EXTENDED_INFORMATION, PLAYERSTATS, 7)
+	response := buildPlayerStatsErrorResponse(7)
+
+	server := NewReplayServer(newReplayFixture(t, outbound(request), inbound(response)))
+
+	if _, err := server.GetPlayerInfo(7); err == nil {
+		t.Fatal("GetPlayerInfo for a rejected client number: got nil error, want an error")
+	}
+}
+
+func TestReplayGetAllPlayerInfoOutOfOrderAndMissing(t *testing.T) {
+	request := buildRequest(EXTENDED_INFORMATION, PLAYERSTATS, -1)
+	cnList := buildCNListResponse([]int{1, 2, 3})
+	// client 3 answers before client 1, and client 2 never answers
+	player3 := buildPlayerStatsResponse(3, 40, "\f1Red", "blue", 2, 0, 1, 0, 0, 100, 100, 0, 0, 0, [4]byte{10, 0, 0, 3})
+	player1 := buildPlayerStatsResponse(1, 55, "Gray", "red", 5, 0, 2, 0, 0, 80, 50, 1, 0, 0, [4]byte{10, 0, 0, 1})
+
+	server := NewReplayServer(newReplayFixture(t, outbound(request), inbound(cnList), inbound(player3), inbound(player1)))
+
+	players, err := server.GetAllPlayerInfo()
+	if err == nil {
+		t.Fatal("GetAllPlayerInfo with a missing client: got nil error, want an error naming the missing client")
+	}
+
+	if len(players) != 2 {
+		t.Fatalf("got %d players, want 2", len(players))
+	}
+
+	byClientNum := map[int]PlayerInfo{}
+	for _, p := range players {
+		byClientNum[p.ClientNum] = p
+	}
+
+	if byClientNum[3].Name != "Red" {
+		t.Errorf("client 3 Name = %q, want cubecode escapes stripped to %q", byClientNum[3].Name, "Red")
+	}
+	if byClientNum[1].Team != "red" {
+		t.Errorf("client 1 Team = %q, want %q", byClientNum[1].Team, "red")
+	}
+}
+
+func TestReplayGetAllPlayerInfoDuplicatePacket(t *testing.T) {
+	request := buildRequest(EXTENDED_INFORMATION, PLAYERSTATS, -1)
+	cnList := buildCNListResponse([]int{1, 2})
+	player1 := buildPlayerStatsResponse(1, 10, "One", "red", 0, 0, 0, 0, 0, 100, 100, 0, 0, 0, [4]byte{10, 0, 0, 1})
+	player2 := buildPlayerStatsResponse(2, 20, "Two", "blue", 0, 0, 0, 0, 0, 100, 100, 0, 0, 0, [4]byte{10, 0, 0, 2})
+
+	server := NewReplayServer(newReplayFixture(t, outbound(request), inbound(cnList), inbound(player1), inbound(player1), inbound(player2)))
+
+	players, err := server.GetAllPlayerInfo()
+	if err != nil {
+		t.Fatalf("GetAllPlayerInfo: %v", err)
+	}
+	if len(players) != 2 {
+		t.Fatalf("got %d players, want 2 (duplicate packet for client 1 should be ignored)", len(players))
+	}
+}
+
+func TestReplayGetTeamScoresTeamMode(t *testing.T) {
+	request := buildRequest(EXTENDED_INFORMATION, TEAM_SCORE, 0)
+	response := buildTeamScoreResponse(true, 120, []fixtureTeam{
+		{name: "good", score: 5, bases: []int{0, 2}},
+		{name: "evil", score: 3, bases: nil},
+	})
+
+	server := NewReplayServer(newReplayFixture(t, outbound(request), inbound(response)))
+
+	scores, err := server.GetTeamScores()
+	if err != nil {
+		t.Fatalf("GetTeamScores: %v", err)
+	}
+	if !scores.IsTeamMode || scores.SecsLeft != 120 {
+		t.Fatalf("unexpected team scores: %+v", scores)
+	}
+	if scores.Teams["good"].Score != 5 || len(scores.Teams["good"].Bases) != 2 {
+		t.Fatalf("team %q = %+v", "good", scores.Teams["good"])
+	}
+	if scores.Teams["evil"].Score != 3 {
+		t.Fatalf("team %q = %+v", "evil", scores.Teams["evil"])
+	}
+}
+
+func TestReplayGetTeamScoresNotTeamMode(t *testing.T) {
+	request := buildRequest(EXTENDED_INFORMATION, TEAM_SCORE, 0)
+	response := buildTeamScoreResponse(false, 60, nil)
+
+	server := NewReplayServer(newReplayFixture(t, outbound(request), inbound(response)))
+
+	scores, err := server.GetTeamScores()
+	if err != nil {
+		t.Fatalf("GetTeamScores: %v", err)
+	}
+	if scores.IsTeamMode {
+		t.Fatal("IsTeamMode = true, want false")
+	}
+	if len(scores.Teams) != 0 {
+		t.Fatalf("Teams = %+v, want empty", scores.Teams)
+	}
+}
+
+func TestReplayGetTeamScoresInvalidBaseCount(t *testing.T) {
+	request := buildRequest(EXTENDED_INFORMATION, TEAM_SCORE, 0)
+	response := buildTeamScoreResponseWithBaseCount("good", 5, -1)
+
+	server := NewReplayServer(newReplayFixture(t, outbound(request), inbound(response)))
+
+	if _, err := server.GetTeamScores(); err == nil {
+		t.Fatal("GetTeamScores with a negative base count: got nil error, want a decode error")
+	}
+}
+
+// TestRecordingAndReplayRoundTrip exercises NewRecordingServer end to end
+// against a fake live server, then replays the capture through
+// NewReplayServer and checks it reproduces the same result without talking
+// to the network at all.
+func TestRecordingAndReplayRoundTrip(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer serverConn.Close()
+
+	response := buildBasicInfoResponse(3, 260, 12, 300, 16, 0, false, false, 0, "ot_bridge", "\f0Red\f7 Test Server")
+
+	go func() {
+		buf := make([]byte, 1024)
+		if _, err := serverConn.Read(buf); err != nil {
+			return
+		}
+		serverConn.Write(response)
+	}()
+
+	liveServer := &Server{
+		addr:       "test",
+		port:       0,
+		timeout:    time.Second,
+		retries:    0,
+		bufferSize: 1024,
+		conn:       clientConn,
+	}
+
+	var recording bytes.Buffer
+	recordingServer := NewRecordingServer(liveServer, &recording)
+
+	recorded, err := recordingServer.GetBasicInfo()
+	if err != nil {
+		t.Fatalf("GetBasicInfo on recording server: %v", err)
+	}
+
+	replayServer := NewReplayServer(bytes.NewReader(recording.Bytes()))
+	replayed, err := replayServer.GetBasicInfo()
+	if err != nil {
+		t.Fatalf("GetBasicInfo on replay server: %v", err)
+	}
+
+	if replayed != recorded {
+		t.Fatalf("replayed info %+v does not match recorded info %+v", replayed, recorded)
+	}
+}
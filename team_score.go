@@ -0,0 +1,84 @@
+package extinfo
+
+import (
+	"fmt"
+
+	"github.com/doneel/extinfo/cubecode"
+)
+
+// TeamScore holds the score of a single team as reported by a TEAM_SCORE query.
+type TeamScore struct {
+	Score int   // the team's current score
+	Bases []int // indices of the flag bases captured by the team this round (modes with bases only)
+}
+
+// TeamScores contains the parsed response to a TEAM_SCORE query.
+type TeamScores struct {
+	IsTeamMode bool                 // whether the current game mode is team-based
+	SecsLeft   int                  // the time left until intermission in seconds
+	Teams      map[string]TeamScore // the current score of each team, keyed by team name
+}
+
+// GetTeamScores queries a Sauerbraten server for the scores of all teams in the current game. If the current game mode is not team-based, IsTeamMode is false and Teams is empty.
+func (s *Server) GetTeamScores() (TeamScores, error) {
+	teamScores := TeamScores{}
+
+	response, err := s.queryServer(buildRequest(EXTENDED_INFORMATION, TEAM_SCORE, 0))
+	if err != nil {
+		return teamScores, err
+	}
+
+	packet := NewPacket(response)
+
+	// first 5 ints are EXTENDED_INFORMATION, TEAM_SCORE, EXT_ACK, EXT_VERSION, EXT_NO_ERROR
+	packet.Skip(5)
+
+	isTeamMode, err := packet.ReadInt()
+	if err != nil {
+		return teamScores, fmt.Errorf("extinfo: decode team mode flag: %w", err)
+	}
+	teamScores.IsTeamMode = isTeamMode != 0
+
+	if teamScores.SecsLeft, err = packet.ReadInt(); err != nil {
+		return teamScores, fmt.Errorf("extinfo: decode seconds left: %w", err)
+	}
+
+	teamScores.Teams = map[string]TeamScore{}
+
+	if !teamScores.IsTeamMode {
+		return teamScores, nil
+	}
+
+	for packet.HasRemaining() {
+		name, err := packet.ReadString()
+		if err != nil {
+			return teamScores, fmt.Errorf("extinfo: decode team name: %w", err)
+		}
+
+		teamScore := TeamScore{}
+
+		if teamScore.Score, err = packet.ReadInt(); err != nil {
+			return teamScores, fmt.Errorf("extinfo: decode score for team %q: %w", name, err)
+		}
+
+		numBases, err := packet.ReadInt()
+		if err != nil {
+			return teamScores, fmt.Errorf("extinfo: decode base count for team %q: %w", name, err)
+		}
+		// a base index is at least 1 byte, so numBases can't exceed the bytes left in the packet
+		if numBases < 0 || numBases > packet.Remaining() {
+			return teamScores, fmt.Errorf("extinfo: invalid base count %d for team %q", numBases, name)
+		}
+
+		teamScore.Bases = make([]int, numBases)
+		for i := range teamScore.Bases {
+			if teamScore.Bases[i], err = packet.ReadInt(); err != nil {
+				return teamScores, fmt.Errorf("extinfo: decode base index for team %q: %w", name, err)
+			}
+		}
+
+		teamScores.Teams[cubecode.SanitizeString(name)] = teamScore
+	}
+
+	return teamScores, nil
+}